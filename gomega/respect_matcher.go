@@ -4,7 +4,6 @@ import (
 	"github.com/JiaYongfei/respect"
 	"github.com/onsi/gomega/format"
 	"github.com/onsi/gomega/types"
-	"strings"
 )
 
 func Respect(expected interface{}, respectOptions ...respect.Options) types.GomegaMatcher {
@@ -26,9 +25,11 @@ func (matcher *respectMatcher) Match(actual interface{}) (success bool, err erro
 }
 
 func (matcher *respectMatcher) FailureMessage(actual interface{}) (message string) {
-	return format.Message(actual, "to respect", matcher.expected) + "\nDisrespect parts are:\n" + strings.Join(matcher.diff, "\n")
+	return format.Message(actual, "to respect", matcher.expected) + "\nDisrespect parts are:\n" +
+		respect.Report(actual, matcher.expected, matcher.options...)
 }
 
 func (matcher *respectMatcher) NegatedFailureMessage(actual interface{}) (message string) {
-	return format.Message(actual, "to not respect", matcher.expected) + "\nDisrespect parts are:\n" + strings.Join(matcher.diff, "\n")
+	return format.Message(actual, "to not respect", matcher.expected) + "\nDisrespect parts are:\n" +
+		respect.Report(actual, matcher.expected, matcher.options...)
 }
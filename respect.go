@@ -1,9 +1,11 @@
 package respect
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
+	"unsafe"
 )
 
 const (
@@ -19,7 +21,75 @@ type cmp struct {
 	diff        []string
 	buff        []string
 	floatFormat string
-	options     Options
+	flags       int
+
+	ignoreFields      map[reflect.Type]map[string]bool
+	ignoreAnchors     []ignoreAnchor
+	ignoreTypes       map[reflect.Type]bool
+	compareUnexported map[reflect.Type]bool // set by IgnoreUnexported, despite the name: opts these types' unexported fields back into comparison
+	comparers         map[reflect.Type]reflect.Value
+	transformers      map[reflect.Type]reflect.Value
+	transforming      map[reflect.Type]bool // guards against a Transformer whose output type is its own input type
+
+	keyByFields map[reflect.Type][]string      // set by KeyBy, consulted by respectSliceIgnoreOrder
+	keyByFuncs  map[reflect.Type]reflect.Value // set by KeyByFunc, func(interface{}) string
+
+	equateErrorsDisabled bool          // set by EquateErrors(false); error comparison is on by default
+	errorComparer        reflect.Value // set by ErrorComparer, func(a, b error) bool
+
+	report *reportWriter // non-nil only when called via Report; see respect_report.go
+
+	maxDepth int
+	visited  map[visitKey]bool
+}
+
+// visitKey identifies one (objVal, respectObjVal) pair respect() has already
+// recursed into, so cyclic data (e.g. a doubly-linked list) doesn't send it
+// into infinite recursion; see cycleKey.
+type visitKey struct {
+	objPtr, respectObjPtr unsafe.Pointer
+	typ                   reflect.Type
+}
+
+// cycleKey returns the visitKey for a Ptr/Map/Slice value (or an Interface
+// wrapping one), and false for anything else, since only those kinds can
+// hold a reference back to something respect() is already visiting.
+func cycleKey(objVal, respectObjVal reflect.Value, typ reflect.Type) (visitKey, bool) {
+	objPtr, ok := pointerOf(objVal)
+	if !ok {
+		return visitKey{}, false
+	}
+	respectObjPtr, ok := pointerOf(respectObjVal)
+	if !ok {
+		return visitKey{}, false
+	}
+	return visitKey{objPtr: objPtr, respectObjPtr: respectObjPtr, typ: typ}, true
+}
+
+func pointerOf(v reflect.Value) (unsafe.Pointer, bool) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return nil, false
+		}
+		return v.UnsafePointer(), true
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil, false
+		}
+		return pointerOf(v.Elem())
+	default:
+		return nil, false
+	}
+}
+
+// ignoreAnchor tracks one active IgnoreFields registration while respect()
+// recurses into the struct it was registered for; bufLen marks where in
+// c.buff that struct started, so later field pushes can be turned back into
+// a path (e.g. "A.B") relative to it and checked against paths.
+type ignoreAnchor struct {
+	bufLen int
+	paths  map[string]bool
 }
 
 var errorType = reflect.TypeOf((*error)(nil)).Elem()
@@ -28,31 +98,30 @@ var errorType = reflect.TypeOf((*error)(nil)).Elem()
 // or nil if there are none.
 //
 // Respect means:
-// 1. if obj and respectObj are primitive types, they should be equal with each other.
-// 2. if obj and respectObj are slice/array type, obj should be a superset of respectObj and elements in obj should
-//    respect the corresponding elements in respectObj. If the slice/array items' kind is reflect.Struct, below is the
-//    way we used to find the corresponding elements.
-//    Use all the valid/non-zero string/*string fields of respectObj as the identifier to find the corresponding element
-//    in obj.
-//    If LengthMatters option provided, they should have same length. If OrderMatters option provided, they'll
-//    be compared one by one in order.
-// 3. if obj and respectObj are map type, obj should contain all the key value pair in respectObj.
-// 4. if obj and respectObj are struct type, obj should contains all the fields and respect their value in respectObj.
-//    Reminder: Be care of the non-pointer field in respectObj struct, these field will be considered as zero value if
-//    omitted and participate into the comparison which might lead to unexpected result
+//  1. if obj and respectObj are primitive types, they should be equal with each other.
+//  2. if obj and respectObj are slice/array type, obj should be a superset of respectObj and elements in obj should
+//     respect the corresponding elements in respectObj. If the slice/array items' kind is reflect.Struct, below is the
+//     way we used to find the corresponding elements.
+//     Use all the valid/non-zero string/*string fields of respectObj as the identifier to find the corresponding element
+//     in obj, unless KeyBy or KeyByFunc registered a key for the item type, in which case that key is used instead.
+//     If LengthMatters option provided, they should have same length. If OrderMatters option provided, they'll
+//     be compared one by one in order.
+//  3. if obj and respectObj are map type, obj should contain all the key value pair in respectObj.
+//  4. if obj and respectObj are struct type, obj should contains all the fields and respect their value in respectObj.
+//     Reminder: Be careful with non-pointer fields in respectObj: an omitted field is its zero value, and zero-valued
+//     fields are skipped rather than compared (unless ZeroValueMatters is set), so omitting a field never fails the
+//     comparison no matter what obj's value is.
 func Respect(obj, respectObj interface{}, respectOptions ...Options) []string {
 	objVal := reflect.ValueOf(obj)
 	respectObjVal := reflect.ValueOf(respectObj)
 
-	var options Options
-	for _, option := range respectOptions {
-		options = options | option
-	}
 	c := &cmp{
 		diff:        []string{},
 		buff:        []string{},
 		floatFormat: fmt.Sprintf("%%.%df", FloatPrecision),
-		options:     options,
+	}
+	for _, option := range respectOptions {
+		option.apply(c)
 	}
 
 	if obj == nil && respectObj == nil {
@@ -84,6 +153,40 @@ func (c *cmp) respect(objVal, respectObjVal reflect.Value, level int) {
 		return
 	}
 
+	if c.maxDepth > 0 && level > c.maxDepth {
+		c.saveMaxDepth()
+		return
+	}
+
+	// If both values implement the error interface, compare them as errors instead of
+	// by concrete type/struct field, which panics for wrapper types or reports irrelevant
+	// diffs. This must run before the type-equality check below, since a wrapped error
+	// (fmt.Errorf("...: %w", err)) and its sentinel are different concrete types on purpose.
+	// On by default; disable with EquateErrors(false). CanInterface is required: a value
+	// obtained from an unexported field (e.g. a struct holding an unexported error) panics
+	// on .Interface(), so treat it like any other unexported value and fall through instead.
+	if !c.equateErrorsDisabled && objVal.CanInterface() && respectObjVal.CanInterface() &&
+		objVal.Type().Implements(errorType) && respectObjVal.Type().Implements(errorType) {
+		objErr, _ := objVal.Interface().(error)
+		respectErr, _ := respectObjVal.Interface().(error)
+		if objErr != nil && respectErr != nil {
+			if c.errorComparer.IsValid() {
+				if !c.errorComparer.Call([]reflect.Value{objVal, respectObjVal})[0].Bool() {
+					c.saveDiff(objErr.Error(), respectErr.Error())
+				}
+				return
+			}
+			// errors.Is first, so sentinel errors like io.EOF match through fmt.Errorf("...: %w", err) wrapping.
+			if errors.Is(objErr, respectErr) {
+				return
+			}
+			if objErr.Error() != respectErr.Error() {
+				c.saveDiff(objErr.Error(), respectErr.Error())
+			}
+			return
+		}
+	}
+
 	// If different types, they can't be equal
 	objType := objVal.Type()
 	respectObjType := respectObjVal.Type()
@@ -105,25 +208,54 @@ func (c *cmp) respect(objVal, respectObjVal reflect.Value, level int) {
 		return
 	}
 
-	// Primitive https://golang.org/pkg/reflect/#Kind
+	// Cycle detection: Ptr/Interface/Map/Slice can all form a back-edge (e.g. a
+	// parent pointing at a child that points back at the parent), which would
+	// otherwise make respect() recurse forever. If we've already compared this
+	// exact pair of values at this type, stop instead of recursing again.
+	if key, ok := cycleKey(objVal, respectObjVal, objType); ok {
+		if c.visited[key] {
+			return
+		}
+		if c.visited == nil {
+			c.visited = map[visitKey]bool{}
+		}
+		c.visited[key] = true
+	}
 
-	// If both types implement the error interface, compare the error strings.
-	// This must be done before dereferencing because the interface is on objVal
-	// pointer receiver. Re https://github.com/go-test/deep/issues/31, objVal/respectObjVal might
-	// be primitive kinds; see TestErrorPrimitiveKind.
-	//if objType.Implements(errorType) && respectObjType.Implements(errorType) {
-	//	if (!objElem || !objVal.IsNil()) && (!respectObjElem || !respectObjVal.IsNil()) {
-	//		aString := objVal.MethodByName("Error").Call(nil)[0].String()
-	//		bString := respectObjVal.MethodByName("Error").Call(nil)[0].String()
-	//		if aString != bString {
-	//			c.saveDiff(aString, bString)
-	//			return
-	//		}
-	//	}
-	//}
+	// Primitive https://golang.org/pkg/reflect/#Kind
 
 	// Ignore the zero values if ZeroValueMatters option not set
-	if c.options&ZeroValueMatters == 0 && respectObjVal.IsZero() {
+	if c.flags&int(ZeroValueMatters) == 0 && respectObjVal.IsZero() {
+		return
+	}
+
+	// IgnoreTypes short-circuits before any of the semantic options below look at the value.
+	if c.ignoreTypes[objType] {
+		return
+	}
+
+	// A registered Comparer takes precedence over the Transformer/Equal()/kind-switch
+	// logic below. Non-nil errors never get here: the error check above handles them.
+	if fn, ok := c.comparers[objType]; ok {
+		if !fn.Call([]reflect.Value{objVal, respectObjVal})[0].Bool() {
+			c.saveDiff(objVal, respectObjVal)
+		}
+		return
+	}
+
+	// A registered Transformer rewrites both sides before the Equal()/kind-switch logic below sees them.
+	// transforming guards against a Transformer whose output type is the same as
+	// its input (e.g. func(string) string), which would otherwise re-trigger
+	// itself forever on its own result.
+	if fn, ok := c.transformers[objType]; ok && !c.transforming[objType] {
+		if c.transforming == nil {
+			c.transforming = map[reflect.Type]bool{}
+		}
+		c.transforming[objType] = true
+		newObjVal := fn.Call([]reflect.Value{objVal})[0]
+		newRespectObjVal := fn.Call([]reflect.Value{respectObjVal})[0]
+		c.respect(newObjVal, newRespectObjVal, level)
+		delete(c.transforming, objType)
 		return
 	}
 
@@ -161,19 +293,37 @@ func (c *cmp) respect(objVal, respectObjVal reflect.Value, level int) {
 			}
 		}
 
+		// IgnoreFields(respectObjType, ...) registers field paths relative to this
+		// struct; remember where in c.buff it started so fields pushed below can
+		// be turned back into a path ("A.B") and checked against it.
+		if paths, ok := c.ignoreFields[respectObjType]; ok {
+			c.ignoreAnchors = append(c.ignoreAnchors, ignoreAnchor{bufLen: len(c.buff), paths: paths})
+			defer func() { c.ignoreAnchors = c.ignoreAnchors[:len(c.ignoreAnchors)-1] }()
+		}
+
 		for i := 0; i < respectObjVal.NumField(); i++ {
-			if respectObjType.Field(i).PkgPath != "" {
-				continue // skip unexported field, e.g. s in type T struct {s string}
+			field := respectObjType.Field(i)
+			if field.PkgPath != "" && !c.compareUnexported[respectObjType] {
+				continue // unexported fields are skipped by default; IgnoreUnexported(T) opts T's back in
 			}
 
-			fieldName := respectObjType.Field(i).Name
+			fieldName := field.Name
 			c.push(fieldName) // push field name to buff
 
+			if c.fieldIgnored() {
+				c.pop()
+				continue
+			}
+
 			// Get the Value for each field, e.g. FirstName has Type = string,
 			// Kind = reflect.String.
 			objF := objVal.FieldByName(fieldName)
 			respectObjF := respectObjVal.Field(i)
 
+			if isContainerKind(respectObjF) {
+				c.report.header(len(c.buff), c.reportLabel())
+			}
+
 			// Recurse to compare the field values
 			c.respect(objF, respectObjF, level+1)
 
@@ -214,6 +364,9 @@ func (c *cmp) respect(objVal, respectObjVal reflect.Value, level int) {
 			aVal := objVal.MapIndex(key)
 			bVal := respectObjVal.MapIndex(key)
 			if aVal.IsValid() {
+				if isContainerKind(bVal) {
+					c.report.header(len(c.buff), c.reportLabel())
+				}
 				c.respect(aVal, bVal, level+1)
 			} else {
 				c.saveDiff("<does not have key>", bVal)
@@ -229,6 +382,9 @@ func (c *cmp) respect(objVal, respectObjVal reflect.Value, level int) {
 		n := respectObjVal.Len()
 		for i := 0; i < n; i++ {
 			c.push(fmt.Sprintf("array[%d]", i))
+			if isContainerKind(respectObjVal.Index(i)) {
+				c.report.header(len(c.buff), c.reportLabel())
+			}
 			c.respect(objVal.Index(i), respectObjVal.Index(i), level+1)
 			c.pop()
 			if len(c.diff) >= MaxDiff {
@@ -259,16 +415,19 @@ func (c *cmp) respect(objVal, respectObjVal reflect.Value, level int) {
 			c.saveDiff_(objLen, respectObjLen, "<")
 			c.pop()
 			return
-		} else if c.options&LengthMatters != 0 {
+		} else if c.flags&int(LengthMatters) != 0 {
 			c.push("<len>")
 			c.saveDiff_(objLen, respectObjLen, ">")
 			c.pop()
 		}
 
-		if c.options&OrderMatters != 0 || respectObjLen <= 1 && objLen == 1 {
+		if c.flags&int(OrderMatters) != 0 || respectObjLen <= 1 && objLen == 1 {
 			// compared one by one
 			for i := 0; i < respectObjLen; i++ {
 				c.push(fmt.Sprintf("[%v]", i))
+				if isContainerKind(respectObjVal.Index(i)) {
+					c.report.header(len(c.buff), c.reportLabel())
+				}
 				c.respect(objVal.Index(i), respectObjVal.Index(i), level+1)
 				c.pop()
 				if len(c.diff) >= MaxDiff {
@@ -296,22 +455,32 @@ func (c *cmp) respect(objVal, respectObjVal reflect.Value, level int) {
 		bval := fmt.Sprintf(c.floatFormat, respectObjVal.Float())
 		if aval != bval {
 			c.saveDiff(objVal.Float(), respectObjVal.Float())
+		} else {
+			c.report.context(len(c.buff), c.reportLabel(), respectObjVal.Float())
 		}
 	case reflect.Bool:
 		if objVal.Bool() != respectObjVal.Bool() {
 			c.saveDiff(objVal.Bool(), respectObjVal.Bool())
+		} else {
+			c.report.context(len(c.buff), c.reportLabel(), respectObjVal.Bool())
 		}
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		if objVal.Int() != respectObjVal.Int() {
 			c.saveDiff(objVal.Int(), respectObjVal.Int())
+		} else {
+			c.report.context(len(c.buff), c.reportLabel(), respectObjVal.Int())
 		}
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		if objVal.Uint() != respectObjVal.Uint() {
 			c.saveDiff(objVal.Uint(), respectObjVal.Uint())
+		} else {
+			c.report.context(len(c.buff), c.reportLabel(), respectObjVal.Uint())
 		}
 	case reflect.String:
 		if objVal.String() != respectObjVal.String() {
 			c.saveDiff(objVal.String(), respectObjVal.String())
+		} else {
+			c.report.context(len(c.buff), c.reportLabel(), respectObjVal.String())
 		}
 	}
 }
@@ -323,34 +492,41 @@ func (c *cmp) respectSliceIgnoreOrder(objVal, respectObjVal reflect.Value, level
 	switch itemKind {
 	case reflect.Struct:
 		respectObjItemVal := valueType(respectObjVal.Index(0))
-		// Use all the valid string/*string field as the identifier
-		var fieldNames []string
-		for i := 0; i < respectObjItemVal.NumField(); i++ {
-			if respectObjItemVal.Field(i).IsValid() &&
-				!respectObjItemVal.Field(i).IsZero() &&
-				valueType(respectObjItemVal.Field(i)).Kind() == reflect.String {
-				fieldNames = append(fieldNames, respectObjItemVal.Type().Field(i).Name)
+		hash, label := c.keyHasher(respectObjItemVal.Type())
+		if hash == nil {
+			// Use all the valid string/*string field as the identifier
+			var fieldNames []string
+			for i := 0; i < respectObjItemVal.NumField(); i++ {
+				if respectObjItemVal.Field(i).IsValid() &&
+					!respectObjItemVal.Field(i).IsZero() &&
+					valueType(respectObjItemVal.Field(i)).Kind() == reflect.String {
+					fieldNames = append(fieldNames, respectObjItemVal.Type().Field(i).Name)
+				}
 			}
-		}
-		if len(fieldNames) == 0 {
-			c.save("<non valid field identifier was found>")
-			return
+			if len(fieldNames) == 0 {
+				c.report.context(len(c.buff), c.reportLabel(), "<non valid field identifier was found>")
+				c.save("<non valid field identifier was found>")
+				return
+			}
+			hash = func(v reflect.Value) string { return structHash(v, fieldNames) }
+			label = strings.Join(fieldNames, "-")
 		}
 		for i := 0; i < respectObjVal.Len(); i++ {
 			c.push(fmt.Sprintf("[%v]", i))
 			respectObjItemVal := valueType(respectObjVal.Index(i))
-			respectHash := structHash(respectObjItemVal, fieldNames)
+			respectHash := hash(respectObjItemVal)
 			found := false
 			for j := 0; j < objVal.Len(); j++ {
 				objItemVal := valueType(objVal.Index(j))
-				if structHash(objItemVal, fieldNames) == respectHash {
+				if hash(objItemVal) == respectHash {
 					found = true
+					c.report.header(len(c.buff), c.reportLabel())
 					c.respect(objVal.Index(j), respectObjVal.Index(i), level+1)
 					break
 				}
 			}
 			if !found {
-				c.push(strings.Join(fieldNames, "-"))
+				c.push(label)
 				c.saveDiff("<not found>", respectHash)
 				c.pop()
 			}
@@ -393,6 +569,43 @@ func structHash(v reflect.Value, fieldNames []string) string {
 	return strings.Join(respectHash, "-")
 }
 
+// keyHasher returns the pairing hash function and not-found label registered
+// for elemType via KeyBy/KeyByFunc, or a nil func if neither was registered,
+// so respectSliceIgnoreOrder can fall back to its string-field autodetection.
+func (c *cmp) keyHasher(elemType reflect.Type) (func(v reflect.Value) string, string) {
+	if fn, ok := c.keyByFuncs[elemType]; ok {
+		return func(v reflect.Value) string {
+			return fn.Call([]reflect.Value{reflect.ValueOf(v.Interface())})[0].String()
+		}, "<KeyByFunc>"
+	}
+	if fieldNames, ok := c.keyByFields[elemType]; ok {
+		return func(v reflect.Value) string { return keyedHash(v, fieldNames) }, strings.Join(fieldNames, "-")
+	}
+	return nil, ""
+}
+
+// keyedHash hashes fieldNames off of v via fmt.Sprintf("%v", ...), unlike structHash
+// it isn't restricted to string-kind fields since the caller named them explicitly.
+func keyedHash(v reflect.Value, fieldNames []string) string {
+	var parts []string
+	for _, fn := range fieldNames {
+		parts = append(parts, fmt.Sprintf("%v", valueType(v.FieldByName(fn)).Interface()))
+	}
+	return strings.Join(parts, "-")
+}
+
+// isContainerKind reports whether v (after one level of pointer/interface
+// dereference) is a Struct/Map/Slice/Array, i.e. a level Report should give
+// its own "label:" header line instead of leaving its children's mismatches
+// to print with no indication of which branch they came from.
+func isContainerKind(v reflect.Value) bool {
+	switch valueType(v).Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	}
+	return false
+}
+
 func valueType(v reflect.Value) reflect.Value {
 	if needDeref(v) {
 		return v.Elem()
@@ -404,6 +617,15 @@ func needDeref(v reflect.Value) bool {
 	return v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface
 }
 
+func contains(indexes []int, i int) bool {
+	for _, idx := range indexes {
+		if idx == i {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *cmp) push(name string) {
 	c.buff = append(c.buff, name)
 }
@@ -414,11 +636,29 @@ func (c *cmp) pop() {
 	}
 }
 
+// fieldIgnored reports whether the field path currently on top of c.buff
+// matches an IgnoreFields path registered for any struct we're still nested
+// inside of (see ignoreAnchor).
+func (c *cmp) fieldIgnored() bool {
+	for _, a := range c.ignoreAnchors {
+		if len(c.buff) > a.bufLen && a.paths[strings.Join(c.buff[a.bufLen:], ".")] {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *cmp) saveDiff(aval, bval interface{}) {
 	c.saveDiff_(aval, bval, "!=")
 }
 
+func (c *cmp) saveMaxDepth() {
+	c.report.context(len(c.buff), c.reportLabel(), "<max depth exceeded>")
+	c.save("<max depth exceeded>")
+}
+
 func (c *cmp) saveDiff_(aval, bval interface{}, operator string) {
+	c.report.mismatch(len(c.buff), c.reportLabel(), aval, bval)
 	c.save(fmt.Sprintf("%v %v %v", aval, operator, bval))
 }
 
@@ -1,11 +1,303 @@
 package respect
 
-//Options is the type for options passed to respect function/matcher.
-type Options int
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Options is the type for options passed to respect function/matcher. Unlike
+// the previous bit-flag int, Options is now an interface so callers can mix
+// the boolean flags below with the semantic options further down this file
+// (IgnoreFields, Comparer, Transformer, ...), in the spirit of go-cmp's cmp.Option.
+type Options interface {
+	apply(c *cmp)
+}
+
+// flag is the Options implementation for the handful of options that are
+// just boolean switches stored as bits on cmp.flags.
+type flag int
 
 const (
 	//OrderMatters will consider the items order when comparing array/slice, rather than triggering a failure.
-	OrderMatters Options = 1 << iota
+	OrderMatters flag = 1 << iota
 	//LengthMatters will consider the length of array/slice when comparing, rather than triggering a failure.
 	LengthMatters
+	//ZeroValueMatters will consider zero valued fields in respectObj significant instead of skipping them.
+	ZeroValueMatters
 )
+
+func (f flag) apply(c *cmp) {
+	c.flags |= int(f)
+}
+
+type maxDepthOption int
+
+func (d maxDepthOption) apply(c *cmp) {
+	c.maxDepth = int(d)
+}
+
+// MaxDepth returns an Options that stops recursing once respect() is depth levels deep, recording a
+// "<max depth exceeded>" diff entry instead. Use it to bound comparison of very deep or unbounded
+// structures instead of letting respect() run away or panic.
+func MaxDepth(depth int) Options {
+	return maxDepthOption(depth)
+}
+
+type ignoreFieldsOption struct {
+	typ   reflect.Type
+	paths map[string]bool
+}
+
+func (o *ignoreFieldsOption) apply(c *cmp) {
+	if c.ignoreFields == nil {
+		c.ignoreFields = map[reflect.Type]map[string]bool{}
+	}
+	c.ignoreFields[o.typ] = o.paths
+}
+
+// IgnoreFields returns an Options that skips the named field paths (e.g. "A.B", "C") while respecting
+// values of structType. Paths are relative to structType and are matched no matter how deeply structType
+// ends up nested inside the value passed to Respect.
+func IgnoreFields(structType interface{}, fieldPaths ...string) Options {
+	paths := make(map[string]bool, len(fieldPaths))
+	for _, p := range fieldPaths {
+		paths[p] = true
+	}
+	return &ignoreFieldsOption{typ: indirectType(reflect.TypeOf(structType)), paths: paths}
+}
+
+type ignoreTypesOption struct {
+	types []reflect.Type
+}
+
+func (o *ignoreTypesOption) apply(c *cmp) {
+	if c.ignoreTypes == nil {
+		c.ignoreTypes = map[reflect.Type]bool{}
+	}
+	for _, t := range o.types {
+		c.ignoreTypes[t] = true
+	}
+}
+
+// IgnoreTypes returns an Options that short-circuits comparison for any value whose reflect.Type
+// matches one of types, no matter where it's encountered while recursing. Non-nil values that both implement
+// error are compared by the error check before IgnoreTypes is consulted, so they are not skipped unless
+// EquateErrors(false) is also passed.
+func IgnoreTypes(types ...interface{}) Options {
+	ts := make([]reflect.Type, 0, len(types))
+	for _, t := range types {
+		ts = append(ts, reflect.TypeOf(t))
+	}
+	return &ignoreTypesOption{types: ts}
+}
+
+type ignoreUnexportedOption struct {
+	types []reflect.Type
+}
+
+func (o *ignoreUnexportedOption) apply(c *cmp) {
+	if c.compareUnexported == nil {
+		c.compareUnexported = map[reflect.Type]bool{}
+	}
+	for _, t := range o.types {
+		c.compareUnexported[t] = true
+	}
+}
+
+// IgnoreUnexported returns an Options that opts the unexported fields of the given struct types back
+// into comparison. Unexported fields are skipped by default, as they always were before the Options
+// system existed: comparing an arbitrary unexported field can panic deeper in respect() (e.g. a
+// Comparer or Transformer is called through reflect, which panics for values obtained from unexported
+// fields; the error check alone skips such values). Only pass a type here when you know its unexported fields are safe
+// to inspect, e.g. a plain data struct in a package you control.
+func IgnoreUnexported(types ...interface{}) Options {
+	ts := make([]reflect.Type, 0, len(types))
+	for _, t := range types {
+		ts = append(ts, indirectType(reflect.TypeOf(t)))
+	}
+	return &ignoreUnexportedOption{types: ts}
+}
+
+type comparerOption struct {
+	typ reflect.Type
+	fn  reflect.Value
+}
+
+func (o *comparerOption) apply(c *cmp) {
+	if c.comparers == nil {
+		c.comparers = map[reflect.Type]reflect.Value{}
+	}
+	c.comparers[o.typ] = o.fn
+}
+
+// Comparer returns an Options that registers fn as the way to respect values of its argument type T.
+// fn must have the signature func(obj, respectObj T) bool and is consulted in respect() after the type,
+// zero-value and IgnoreTypes checks, before the default kind-based comparison. Non-nil values that both
+// implement error are compared by the error check instead and never reach fn unless EquateErrors(false) is passed.
+func Comparer(fn interface{}) Options {
+	fnType := reflect.TypeOf(fn)
+	if fnType == nil || fnType.Kind() != reflect.Func || fnType.NumIn() != 2 || fnType.In(0) != fnType.In(1) ||
+		fnType.NumOut() != 1 || fnType.Out(0).Kind() != reflect.Bool {
+		panic(fmt.Sprintf("respect: Comparer needs func(T, T) bool, got %T", fn))
+	}
+	return &comparerOption{typ: fnType.In(0), fn: reflect.ValueOf(fn)}
+}
+
+type equateApproxOption struct {
+	fraction, margin float64
+}
+
+func (o *equateApproxOption) apply(c *cmp) {
+	eq := func(a, b float64) bool {
+		if a == b {
+			return true
+		}
+		delta := math.Abs(a - b)
+		return delta <= o.margin || delta <= o.fraction*math.Max(math.Abs(a), math.Abs(b))
+	}
+	registerFloatComparers(c, eq)
+}
+
+// EquateApprox returns an Options that treats two float32/float64 values as respecting each other when
+// |a-b| <= max(margin, fraction*max(|a|,|b|)), instead of the default FloatPrecision decimal rounding.
+// Passing it alongside another float-tuning option such as EquateNaNs means whichever was passed last wins,
+// same as registering two Comparers for the same type.
+func EquateApprox(fraction, margin float64) Options {
+	return &equateApproxOption{fraction: fraction, margin: margin}
+}
+
+type equateNaNsOption struct{}
+
+func (equateNaNsOption) apply(c *cmp) {
+	eq := func(a, b float64) bool {
+		if math.IsNaN(a) && math.IsNaN(b) {
+			return true
+		}
+		format := fmt.Sprintf("%%.%df", FloatPrecision)
+		return fmt.Sprintf(format, a) == fmt.Sprintf(format, b)
+	}
+	registerFloatComparers(c, eq)
+}
+
+// EquateNaNs returns an Options that explicitly treats two NaN float32/float64 values as respecting
+// each other (the default FloatPrecision rounding happens to do this already, since both format as the
+// string "NaN", but that's incidental; EquateNaNs makes the intent explicit). Non-NaN values still use
+// the default FloatPrecision rounding.
+func EquateNaNs() Options {
+	return equateNaNsOption{}
+}
+
+// registerFloatComparers installs eq, a func(float64, float64) bool, as the Comparer for both
+// float64 and float32 (the latter via a thin wrapper), sharing one implementation between them.
+func registerFloatComparers(c *cmp, eq func(a, b float64) bool) {
+	if c.comparers == nil {
+		c.comparers = map[reflect.Type]reflect.Value{}
+	}
+	c.comparers[reflect.TypeOf(float64(0))] = reflect.ValueOf(eq)
+	c.comparers[reflect.TypeOf(float32(0))] = reflect.ValueOf(func(a, b float32) bool {
+		return eq(float64(a), float64(b))
+	})
+}
+
+type keyByOption struct {
+	typ    reflect.Type
+	fields []string
+}
+
+func (o *keyByOption) apply(c *cmp) {
+	if c.keyByFields == nil {
+		c.keyByFields = map[reflect.Type][]string{}
+	}
+	c.keyByFields[o.typ] = o.fields
+}
+
+// KeyBy returns an Options that pairs elemType items between obj and respectObj in
+// respectSliceIgnoreOrder by hashing fieldNames (of any kind, via fmt.Sprintf("%v", ...))
+// instead of the default autodetection, which only considers non-zero string/*string fields
+// of the first respectObj item. Use it when items are keyed by integers, UUIDs, or a
+// composite of non-string fields, e.g. KeyBy(Container{}, "Name") or KeyBy(Port{}, "Protocol", "Port").
+func KeyBy(elemType interface{}, fieldNames ...string) Options {
+	return &keyByOption{typ: indirectType(reflect.TypeOf(elemType)), fields: fieldNames}
+}
+
+type keyByFuncOption struct {
+	typ reflect.Type
+	fn  reflect.Value
+}
+
+func (o *keyByFuncOption) apply(c *cmp) {
+	if c.keyByFuncs == nil {
+		c.keyByFuncs = map[reflect.Type]reflect.Value{}
+	}
+	c.keyByFuncs[o.typ] = o.fn
+}
+
+// KeyByFunc returns an Options like KeyBy, but derives the pairing key for elemType items
+// by calling fn instead of hashing named fields, for keys that can't be expressed as a
+// fixed field list.
+func KeyByFunc(elemType interface{}, fn func(interface{}) string) Options {
+	return &keyByFuncOption{typ: indirectType(reflect.TypeOf(elemType)), fn: reflect.ValueOf(fn)}
+}
+
+type equateErrorsOption struct {
+	enable bool
+}
+
+func (o equateErrorsOption) apply(c *cmp) {
+	c.equateErrorsDisabled = !o.enable
+}
+
+// EquateErrors returns an Options that toggles the error-interface comparison respect() performs
+// whenever both sides implement error: errors.Is(obj, respectObj) first, so wrapped errors created
+// with fmt.Errorf("...: %w", err) and sentinel errors like io.EOF still match, falling back to
+// comparing Error() strings. It's on by default, so EquateErrors(false) is the call that actually
+// changes anything; EquateErrors(true) is only useful to re-enable it after an earlier
+// EquateErrors(false) in the same option list.
+func EquateErrors(enable bool) Options {
+	return equateErrorsOption{enable: enable}
+}
+
+type errorComparerOption struct {
+	fn reflect.Value
+}
+
+func (o errorComparerOption) apply(c *cmp) {
+	c.errorComparer = o.fn
+}
+
+// ErrorComparer returns an Options that replaces the default errors.Is/Error() string comparison
+// with fn for callers who need custom error semantics, e.g. matching gRPC status codes instead of
+// comparing wrapped error chains.
+func ErrorComparer(fn func(a, b error) bool) Options {
+	return errorComparerOption{fn: reflect.ValueOf(fn)}
+}
+
+type transformerOption struct {
+	typ reflect.Type
+	fn  reflect.Value
+}
+
+func (o *transformerOption) apply(c *cmp) {
+	if c.transformers == nil {
+		c.transformers = map[reflect.Type]reflect.Value{}
+	}
+	c.transformers[o.typ] = o.fn
+}
+
+// Transformer returns an Options that rewrites both sides through fn, of signature func(T) R, before
+// they're compared. name is purely documentation, it has no effect on the comparison.
+func Transformer(name string, fn interface{}) Options {
+	fnType := reflect.TypeOf(fn)
+	if fnType == nil || fnType.Kind() != reflect.Func || fnType.NumIn() != 1 || fnType.NumOut() != 1 {
+		panic(fmt.Sprintf("respect: Transformer %q needs func(T) R, got %T", name, fn))
+	}
+	return &transformerOption{typ: fnType.In(0), fn: reflect.ValueOf(fn)}
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,115 @@
+package respect
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Report works like Respect, but instead of a flat list of "path: a != b"
+// strings it renders an indented tree following respectObj's own shape: each
+// value that disrespects is shown as a "-" (actual) line followed by a "+"
+// (expected) one, cmp.Diff style, while everything else is printed as plain,
+// unmarked context so the mismatches can be read in place.
+func Report(obj, respectObj interface{}, respectOptions ...Options) string {
+	objVal := reflect.ValueOf(obj)
+	respectObjVal := reflect.ValueOf(respectObj)
+
+	c := &cmp{
+		diff:        []string{},
+		buff:        []string{},
+		floatFormat: fmt.Sprintf("%%.%df", FloatPrecision),
+		report:      &reportWriter{},
+	}
+	for _, option := range respectOptions {
+		option.apply(c)
+	}
+
+	if obj == nil && respectObj == nil {
+		return ""
+	} else if obj == nil && respectObj != nil {
+		c.saveDiff("<nil pointer>", respectObj)
+	} else if obj != nil && respectObj == nil {
+		c.saveDiff(obj, "<nil pointer>")
+	}
+	if len(c.diff) == 0 {
+		c.respect(objVal, respectObjVal, 0)
+	}
+
+	return c.report.render()
+}
+
+// reportLine is one line of a Report. depth is how many field/item/key
+// levels it's nested under, marker is ' ' for unmarked context, '-' for the
+// actual value and '+' for the expected one.
+type reportLine struct {
+	depth  int
+	marker byte
+	text   string
+}
+
+// reportWriter buffers reportLines in the order respect() visits them and
+// renders them at the end, indented per depth. A nil *reportWriter absorbs
+// every call as a no-op so Respect (which never sets c.report) doesn't pay
+// for any of this.
+type reportWriter struct {
+	lines []reportLine
+}
+
+func (w *reportWriter) context(depth int, label string, val interface{}) {
+	if w == nil {
+		return
+	}
+	w.lines = append(w.lines, reportLine{depth: depth, marker: ' ', text: reportText(label, val)})
+}
+
+func (w *reportWriter) mismatch(depth int, label string, aval, bval interface{}) {
+	if w == nil {
+		return
+	}
+	w.lines = append(w.lines,
+		reportLine{depth: depth, marker: '-', text: reportText(label, aval)},
+		reportLine{depth: depth, marker: '+', text: reportText(label, bval)},
+	)
+}
+
+// header marks entry into a struct/map/slice/array level with a plain "label:" line, so the mismatches
+// printed by its children (one level deeper) read as "Body: / Head: / - Mouth: ..." instead of an
+// unlabeled leaf with no indication of which branch it came from.
+func (w *reportWriter) header(depth int, label string) {
+	if w == nil || label == "" {
+		return
+	}
+	w.lines = append(w.lines, reportLine{depth: depth, marker: ' ', text: label + ":"})
+}
+
+func reportText(label string, val interface{}) string {
+	if label == "" {
+		return fmt.Sprintf("%v", val)
+	}
+	return fmt.Sprintf("%s: %v", label, val)
+}
+
+func (w *reportWriter) render() string {
+	if w == nil || len(w.lines) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for _, l := range w.lines {
+		b.WriteByte(l.marker)
+		b.WriteByte(' ')
+		b.WriteString(strings.Repeat("  ", l.depth))
+		b.WriteString(l.text)
+		b.WriteByte('\n')
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// reportLabel is the field/item/key name respect() is currently looking at,
+// i.e. the last segment pushed onto c.buff.
+func (c *cmp) reportLabel() string {
+	if len(c.buff) == 0 {
+		return ""
+	}
+	return c.buff[len(c.buff)-1]
+}
@@ -0,0 +1,13 @@
+package test_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestRespect(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Respect Suite")
+}
@@ -1,7 +1,12 @@
 package test_test
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"strings"
+
 	"github.com/JiaYongfei/respect"
 	. "github.com/JiaYongfei/respect/gomega"
 	. "github.com/onsi/ginkgo"
@@ -40,6 +45,25 @@ type Person struct {
 	Memory      map[string]string
 }
 
+type Node struct {
+	Name string
+	Next *Node
+}
+
+type Port struct {
+	Protocol string
+	Number   int
+}
+
+type withUnexpErr struct {
+	name string
+	err  error
+}
+
+type withUnexpField struct {
+	code int
+}
+
 var (
 	obj *Person
 
@@ -118,18 +142,26 @@ var _ = Describe("Test", func() {
 	})
 
 	Context("Struct", func() {
-		It("Required value must be provided to avoid unexpected result", func() {
-			Ω(obj).ShouldNot(Respect(&Person{
+		It("Omitted (zero-valued) fields in respectObj are skipped, not required", func() {
+			Ω(obj).Should(Respect(&Person{
 				Name: "NeZha",
-				//Age: int32(3), // Required field value will be zero if not provided
+				//Age: int32(3), // zero-valued Age is skipped, not compared
 				Color: ColorYellow,
 			}))
 			Ω(obj).Should(Respect(&Person{
 				Name:  "NeZha",
-				Age:   int32(3), // Required value provided
+				Age:   int32(3), // explicitly matching value is also respected
 				Color: ColorYellow,
 			}))
 		})
+
+		It("ZeroValueMatters makes omitted fields significant", func() {
+			Ω(obj).ShouldNot(Respect(&Person{
+				Name: "NeZha",
+				//Age: int32(3), // zero Age now compared against obj's Age (3), and fails
+				Color: ColorYellow,
+			}, respect.ZeroValueMatters))
+		})
 	})
 
 	Context("Slice", func() {
@@ -211,4 +243,197 @@ var _ = Describe("Test", func() {
 			}, respect.LengthMatters))
 		})
 	})
+
+	Context("Options", func() {
+		It("IgnoreFields skips the named field path", func() {
+			Ω(obj).ShouldNot(Respect(&Person{
+				Name:  "NeZha",
+				Age:   int32(3),
+				Color: ColorYellow,
+				Body: Body{
+					Head: &Head{
+						Mouth: &mouthSmall, // Wrong mouth
+					},
+				},
+			}))
+			Ω(obj).Should(Respect(&Person{
+				Name:  "NeZha",
+				Age:   int32(3),
+				Color: ColorYellow,
+				Body: Body{
+					Head: &Head{
+						Mouth: &mouthSmall, // Wrong mouth, but ignored below
+					},
+				},
+			}, respect.IgnoreFields(Body{}, "Head.Mouth")))
+		})
+
+		It("IgnoreTypes skips every value of the given type", func() {
+			Ω(obj).Should(Respect(&Person{
+				Name:  "NeZha",
+				Age:   int32(3),
+				Color: ColorYellow,
+				Body: Body{
+					Head: &Head{
+						Mouth: &mouthSmall, // Wrong mouth, but Head is ignored entirely
+					},
+				},
+			}, respect.IgnoreTypes(Head{})))
+		})
+
+		It("unexported fields are skipped by default, even when they hold a non-zero error", func() {
+			Ω(withUnexpErr{name: "a", err: errors.New("boom")}).
+				Should(Respect(withUnexpErr{name: "a", err: errors.New("different")}))
+		})
+
+		It("IgnoreUnexported opts a type's unexported fields back into comparison", func() {
+			Ω(withUnexpField{code: 1}).
+				ShouldNot(Respect(withUnexpField{code: 2}, respect.IgnoreUnexported(withUnexpField{})))
+			Ω(withUnexpField{code: 1}).
+				Should(Respect(withUnexpField{code: 1}, respect.IgnoreUnexported(withUnexpField{})))
+		})
+
+		It("Comparer is consulted before the default comparison", func() {
+			sameLength := respect.Comparer(func(a, b string) bool {
+				return len(a) == len(b)
+			})
+			Ω(obj).Should(Respect(&Person{
+				Name: "NeZho", // same length as "NeZha", different content
+			}, sameLength))
+		})
+
+		It("Transformer rewrites both sides before comparison", func() {
+			upper := respect.Transformer("toUpper", strings.ToUpper)
+			Ω(obj).Should(Respect(&Person{
+				Name: "nezha",
+			}, upper))
+		})
+	})
+
+	Context("Custom slice key selector", func() {
+		ports := []Port{
+			{Protocol: "TCP", Number: 80},
+			{Protocol: "UDP", Number: 53},
+		}
+
+		It("KeyBy pairs items by non-string fields instead of autodetection", func() {
+			Ω(&ports).Should(Respect(&[]Port{
+				{Protocol: "UDP", Number: 53},
+				{Protocol: "TCP", Number: 80},
+			}, respect.KeyBy(Port{}, "Protocol", "Number")))
+			Ω(&ports).ShouldNot(Respect(&[]Port{
+				{Protocol: "UDP", Number: 53},
+				{Protocol: "TCP", Number: 8080}, // wrong port number
+			}, respect.KeyBy(Port{}, "Protocol", "Number")))
+		})
+
+		It("KeyByFunc derives the pairing key by calling fn", func() {
+			byProtocol := respect.KeyByFunc(Port{}, func(v interface{}) string {
+				return v.(Port).Protocol
+			})
+			Ω(&ports).Should(Respect(&[]Port{
+				{Protocol: "UDP", Number: 53},
+				{Protocol: "TCP", Number: 80},
+			}, byProtocol))
+		})
+	})
+
+	Context("Approximate float equality", func() {
+		type Measurement struct {
+			Value float64
+		}
+
+		It("EquateApprox respects values within tolerance", func() {
+			Ω(&Measurement{Value: 1.0001}).ShouldNot(Respect(&Measurement{Value: 1.0}))
+			Ω(&Measurement{Value: 1.0001}).Should(Respect(&Measurement{Value: 1.0}, respect.EquateApprox(0, 0.001)))
+			Ω(&Measurement{Value: 1.1}).ShouldNot(Respect(&Measurement{Value: 1.0}, respect.EquateApprox(0, 0.001)))
+		})
+
+		It("EquateNaNs respects two NaNs", func() {
+			nan := math.NaN()
+			Ω(&Measurement{Value: nan}).Should(Respect(&Measurement{Value: nan}, respect.EquateNaNs()))
+		})
+	})
+
+	Context("Errors", func() {
+		It("respects wrapped errors against their sentinel via errors.Is", func() {
+			wrapped := fmt.Errorf("while handling request: %w", io.EOF)
+			Ω(wrapped).Should(Respect(io.EOF))
+			Ω(wrapped).ShouldNot(Respect(errors.New("EOF")))
+		})
+
+		It("falls back to comparing Error() strings for unrelated errors", func() {
+			Ω(errors.New("boom")).Should(Respect(errors.New("boom")))
+			Ω(errors.New("boom")).ShouldNot(Respect(errors.New("bang")))
+		})
+
+		It("EquateErrors(false) disables error-aware comparison", func() {
+			wrapped := fmt.Errorf("while handling request: %w", io.EOF)
+			Ω(wrapped).ShouldNot(Respect(io.EOF, respect.EquateErrors(false)))
+		})
+
+		It("ErrorComparer overrides the default comparison", func() {
+			alwaysEqual := respect.ErrorComparer(func(a, b error) bool { return true })
+			Ω(errors.New("boom")).Should(Respect(errors.New("bang"), alwaysEqual))
+		})
+	})
+
+	Context("Cycle detection", func() {
+		It("doesn't stack overflow on self-referential structures", func() {
+			a := &Node{Name: "a"}
+			b := &Node{Name: "b"}
+			a.Next = b
+			b.Next = a
+
+			respectA := &Node{Name: "a"}
+			respectB := &Node{Name: "b"}
+			respectA.Next = respectB
+			respectB.Next = respectA
+
+			Ω(a).Should(Respect(respectA))
+
+			respectB.Name = "different"
+			Ω(a).ShouldNot(Respect(respectA))
+		})
+
+		It("stops at MaxDepth instead of recursing further", func() {
+			a := &Node{Name: "a", Next: &Node{Name: "b", Next: &Node{Name: "c"}}}
+			Ω(a).ShouldNot(Respect(&Node{Name: "a", Next: &Node{Name: "b", Next: &Node{Name: "c"}}}, respect.MaxDepth(2)))
+		})
+	})
+
+	Context("Report", func() {
+		It("marks mismatches with - and + and leaves the rest as context", func() {
+			report := respect.Report(obj, &Person{
+				Name:  "NeZha",
+				Age:   int32(3),
+				Color: ColorYellow,
+				Body: Body{
+					Head: &Head{
+						Mouth: &mouthSmall, // Wrong mouth
+					},
+				},
+			})
+			Ω(report).Should(ContainSubstring("-"))
+			Ω(report).Should(ContainSubstring("Mouth: " + mouthBig))
+			Ω(report).Should(ContainSubstring("+"))
+			Ω(report).Should(ContainSubstring("Mouth: " + mouthSmall))
+			Ω(report).Should(ContainSubstring("Name: NeZha"))
+		})
+
+		It("labels each struct/map/slice level so nested mismatches are traceable to their branch", func() {
+			report := respect.Report(obj, &Person{
+				Name:  "NeZha",
+				Age:   int32(3),
+				Color: ColorYellow,
+				Body: Body{
+					Head: &Head{
+						Mouth: &mouthSmall, // Wrong mouth
+					},
+				},
+			})
+			Ω(report).Should(ContainSubstring("Body:"))
+			Ω(report).Should(ContainSubstring("Head:"))
+		})
+	})
 })